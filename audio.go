@@ -0,0 +1,156 @@
+package gocaptcha
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+)
+
+//go:embed assets/audio/en assets/audio/zh
+var audioSamples embed.FS
+
+const (
+	audioSampleRate = 8000
+	//audioMinSilenceMs/audioMaxSilenceMs 两个采样之间随机静音时长(毫秒),用于打乱固定节奏防止机器识别.
+	audioMinSilenceMs = 80
+	audioMaxSilenceMs = 220
+	//audioNoiseLevel 叠加的背景白噪声幅度,用于干扰 OCR/ASR.
+	audioNoiseLevel = 250
+)
+
+//AudioCaptcha 语音验证码,与 CaptchaImage 共用 RandText 生成的文本作为答案.
+type AudioCaptcha struct {
+	text string
+	lang string
+
+	Error error
+}
+
+//NewAudio 新建一个语音验证码对象,text 通常来自 RandText 生成的数字串,lang 为 "en" 或 "zh".
+func NewAudio(text string, lang string) *AudioCaptcha {
+	return &AudioCaptcha{text: text, lang: lang}
+}
+
+//loadDigitSample 读取指定语言/数字的内置 WAV 采样,返回其 PCM 数据(已剥离 WAV 头).
+func loadDigitSample(lang string, digit byte) ([]byte, error) {
+	if digit < '0' || digit > '9' {
+		return nil, errors.New("gocaptcha: audio captcha only supports digit characters")
+	}
+
+	raw, err := audioSamples.ReadFile("assets/audio/" + lang + "/" + string(digit) + ".wav")
+	if err != nil {
+		return nil, err
+	}
+
+	return pcmFromWav(raw)
+}
+
+//pcmFromWav 从标准 WAV 字节流中取出 data 块,忽略其余 chunk.
+func pcmFromWav(raw []byte) ([]byte, error) {
+	if len(raw) < 44 || string(raw[0:4]) != "RIFF" || string(raw[8:12]) != "WAVE" {
+		return nil, errors.New("gocaptcha: invalid embedded wav sample")
+	}
+
+	offset := 12
+	for offset+8 <= len(raw) {
+		id := string(raw[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(raw[offset+4 : offset+8]))
+		offset += 8
+		if id == "data" {
+			if offset+size > len(raw) {
+				size = len(raw) - offset
+			}
+			return raw[offset : offset+size], nil
+		}
+		offset += size
+	}
+
+	return nil, errors.New("gocaptcha: wav sample has no data chunk")
+}
+
+//silencePCM 生成指定毫秒数的静音采样(叠加轻微白噪声防止被截断检测跳过).
+func silencePCM(ms int) []byte {
+	n := audioSampleRate * ms / 1000
+	buf := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		noise := int16(rand.Intn(2*audioNoiseLevel) - audioNoiseLevel)
+		binary.LittleEndian.PutUint16(buf[i*2:i*2+2], uint16(noise))
+	}
+	return buf
+}
+
+//addBackgroundNoise 在已有 PCM 采样上叠加轻微背景白噪声.
+func addBackgroundNoise(pcm []byte) []byte {
+	out := make([]byte, len(pcm))
+	copy(out, pcm)
+	for i := 0; i+1 < len(out); i += 2 {
+		v := int16(binary.LittleEndian.Uint16(out[i : i+2]))
+		noise := int16(rand.Intn(2*audioNoiseLevel) - audioNoiseLevel)
+		v += noise / 4
+		binary.LittleEndian.PutUint16(out[i:i+2], uint16(v))
+	}
+	return out
+}
+
+//SaveAudio 将验证码文本渲染为 8kHz 单声道 PCM WAV 并写入 w.
+func (audio *AudioCaptcha) SaveAudio(w io.Writer) error {
+	if audio.Error != nil {
+		return audio.Error
+	}
+
+	lang := audio.lang
+	if lang != "zh" {
+		lang = "en"
+	}
+
+	var pcm bytes.Buffer
+	for i, ch := range audio.text {
+		if i > 0 {
+			pcm.Write(silencePCM(audioMinSilenceMs + rand.Intn(audioMaxSilenceMs-audioMinSilenceMs+1)))
+		}
+
+		sample, err := loadDigitSample(lang, byte(ch))
+		if err != nil {
+			return err
+		}
+		pcm.Write(addBackgroundNoise(sample))
+	}
+
+	return writeWav(w, pcm.Bytes())
+}
+
+//writeWav 写出标准 8kHz 单声道 16bit PCM WAV 文件.
+func writeWav(w io.Writer, pcm []byte) error {
+	const (
+		numChannels   = 1
+		bitsPerSample = 16
+	)
+	byteRate := audioSampleRate * numChannels * bitsPerSample / 8
+	blockAlign := numChannels * bitsPerSample / 8
+
+	header := new(bytes.Buffer)
+	header.WriteString("RIFF")
+	binary.Write(header, binary.LittleEndian, uint32(36+len(pcm)))
+	header.WriteString("WAVE")
+
+	header.WriteString("fmt ")
+	binary.Write(header, binary.LittleEndian, uint32(16))
+	binary.Write(header, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(header, binary.LittleEndian, uint16(numChannels))
+	binary.Write(header, binary.LittleEndian, uint32(audioSampleRate))
+	binary.Write(header, binary.LittleEndian, uint32(byteRate))
+	binary.Write(header, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(header, binary.LittleEndian, uint16(bitsPerSample))
+
+	header.WriteString("data")
+	binary.Write(header, binary.LittleEndian, uint32(len(pcm)))
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(pcm)
+	return err
+}
@@ -0,0 +1,38 @@
+package gocaptcha
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSaveAudioProducesValidWav(t *testing.T) {
+	audio := NewAudio("123", "en")
+
+	var buf bytes.Buffer
+	if err := audio.SaveAudio(&buf); err != nil {
+		t.Fatalf("SaveAudio returned error: %v", err)
+	}
+
+	data := buf.Bytes()
+	if len(data) < 44 {
+		t.Fatalf("wav output too short: %d bytes", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		t.Fatalf("missing RIFF/WAVE header: %q", data[:12])
+	}
+
+	sampleRate := binary.LittleEndian.Uint32(data[24:28])
+	if sampleRate != audioSampleRate {
+		t.Fatalf("sample rate = %d, want %d", sampleRate, audioSampleRate)
+	}
+}
+
+func TestSaveAudioRejectsNonDigitText(t *testing.T) {
+	audio := NewAudio("A1", "en")
+
+	var buf bytes.Buffer
+	if err := audio.SaveAudio(&buf); err == nil {
+		t.Fatal("expected error for non-digit text, got nil")
+	}
+}
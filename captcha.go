@@ -7,22 +7,18 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
-	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"math"
 	"math/rand"
-	"time"
+	"unicode/utf8"
 
 	"github.com/golang/freetype"
 	"golang.org/x/image/font"
 )
 
-var (
-	dpi        = flag.Float64("dpi", 72, "screen resolution in Dots Per Inch")
-	fontFamily = make([]string, 0)
-)
+var dpi = flag.Float64("dpi", 72, "screen resolution in Dots Per Inch")
 
 const txtChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 
@@ -43,12 +39,25 @@ type ImageFormat int
 
 type CaptchaComplex int
 
+//Point 二维整数坐标,用于直线/圆等绘制原语传参.
+type Point struct {
+	X int
+	Y int
+}
+
 type CaptchaImage struct {
 	nrgba   *image.NRGBA
 	width   int
 	height  int
 	Complex int
 	Error   error
+
+	charSource   CharSource
+	fontsStorage FontsStorage
+	fontNames    []string
+
+	//rnd 每张图片独立的随机源,避免高并发下争用全局 math/rand 锁.
+	rnd *rand.Rand
 }
 
 //NewCaptchaImage 新建一个图片对象
@@ -67,6 +76,7 @@ func New(width int, height int, bgColor color.RGBA) *CaptchaImage {
 		nrgba:  m,
 		height: height,
 		width:  width,
+		rnd:    rand.New(rand.NewSource(nextSeed())),
 	}
 }
 
@@ -80,7 +90,7 @@ func (captcha *CaptchaImage) SaveImage(w io.Writer, imageFormat ImageFormat) err
 		return jpeg.Encode(w, captcha.nrgba, &jpeg.Options{Quality: 100})
 	}
 	if imageFormat == ImageFormatGif {
-		return gif.Encode(w, captcha.nrgba, &gif.Options{NumColors: 256})
+		return captcha.SaveAnimatedGIF(w, defaultGifFrames, defaultGifDelayCS)
 	}
 
 	return errors.New("not supported image format")
@@ -96,11 +106,11 @@ func (captcha *CaptchaImage) DrawHollowLine() *CaptchaImage {
 
 	lineColor := color.RGBA{R: 245, G: 250, B: 251, A: 255}
 
-	x1 := float64(rand.Intn(first))
+	x1 := float64(captcha.safeIntn(first))
 
-	x2 := float64(rand.Intn(first) + end)
+	x2 := float64(captcha.safeIntn(first) + end)
 
-	multiple := float64(rand.Intn(5)+3) / float64(5)
+	multiple := float64(captcha.safeIntn(5)+3) / float64(5)
 	if int(multiple*10)%3 == 0 {
 		multiple = multiple * -1.0
 	}
@@ -126,6 +136,11 @@ func (captcha *CaptchaImage) DrawHollowLine() *CaptchaImage {
 
 //DrawSineLine 画一条曲线.
 func (captcha *CaptchaImage) DrawSineLine() *CaptchaImage {
+	return captcha.sineLine(0)
+}
+
+//sineLine DrawSineLine 的内部实现,phaseOffset 用于动态 GIF 逐帧偏移相位.
+func (captcha *CaptchaImage) sineLine(phaseOffset float64) *CaptchaImage {
 	if captcha.Error != nil {
 		return captcha
 	}
@@ -133,31 +148,31 @@ func (captcha *CaptchaImage) DrawSineLine() *CaptchaImage {
 	var py float64 = 0
 
 	//振幅
-	a := rand.Intn(captcha.height / 2)
+	a := captcha.safeIntn(captcha.height / 2)
 
 	//Y轴方向偏移量
-	b := Random(int64(-captcha.height/4), int64(captcha.height/4))
+	b := captcha.random(int64(-captcha.height/4), int64(captcha.height/4))
 
 	//X轴方向偏移量
-	f := Random(int64(-captcha.height/4), int64(captcha.height/4))
+	f := captcha.random(int64(-captcha.height/4), int64(captcha.height/4))
 	// 周期
 	var t float64 = 0
 	if captcha.height > captcha.width/2 {
-		t = Random(int64(captcha.width/2), int64(captcha.height))
+		t = captcha.random(int64(captcha.width/2), int64(captcha.height))
 	} else {
-		t = Random(int64(captcha.height), int64(captcha.width/2))
+		t = captcha.random(int64(captcha.height), int64(captcha.width/2))
 	}
 	w := (2 * math.Pi) / t
 
 	// 曲线横坐标起始位置
 	px1 := 0
-	px2 := int(Random(int64(float64(captcha.width)*0.8), int64(captcha.width)))
+	px2 := int(captcha.random(int64(float64(captcha.width)*0.8), int64(captcha.width)))
 
-	c := color.RGBA{R: uint8(rand.Intn(150)), G: uint8(rand.Intn(150)), B: uint8(rand.Intn(150)), A: uint8(255)}
+	c := color.RGBA{R: uint8(captcha.safeIntn(150)), G: uint8(captcha.safeIntn(150)), B: uint8(captcha.safeIntn(150)), A: uint8(255)}
 
 	for px = px1; px < px2; px++ {
 		if w != 0 {
-			py = float64(a)*math.Sin(w*float64(px)+f) + b + (float64(captcha.width) / float64(5))
+			py = float64(a)*math.Sin(w*float64(px)+f+phaseOffset) + b + (float64(captcha.width) / float64(5))
 			i := captcha.height / 5
 			for i > 0 {
 				captcha.nrgba.Set(px+i, int(py), c)
@@ -181,57 +196,85 @@ func (captcha *CaptchaImage) DrawLine(num int) *CaptchaImage {
 
 	for i := 0; i < num; i++ {
 
-		point1 := Point{X: rand.Intn(first), Y: rand.Intn(y)}
-		point2 := Point{X: rand.Intn(first) + end, Y: rand.Intn(y)}
+		point1 := Point{X: captcha.safeIntn(first), Y: captcha.safeIntn(y)}
+		point2 := Point{X: captcha.safeIntn(first) + end, Y: captcha.safeIntn(y)}
 
 		if i%2 == 0 {
-			point1.Y = rand.Intn(y) + y*2
-			point2.Y = rand.Intn(y)
+			point1.Y = captcha.safeIntn(y) + y*2
+			point2.Y = captcha.safeIntn(y)
 		} else {
-			point1.Y = rand.Intn(y) + y*(i%2)
-			point2.Y = rand.Intn(y) + y*2
+			point1.Y = captcha.safeIntn(y) + y*(i%2)
+			point2.Y = captcha.safeIntn(y) + y*2
 		}
 
-		captcha.drawBeeline(point1, point2, randDeepColor())
+		captcha.drawBeeline(point1, point2, captcha.randDeepColor())
 
 	}
 	return captcha
 }
 
-//drawBeeline 画直线.
+//drawBeeline 画直线,线宽固定为 5 像素以贴近原有视觉效果.
 func (captcha *CaptchaImage) drawBeeline(point1 Point, point2 Point, lineColor color.RGBA) *CaptchaImage {
+	return captcha.drawLineWidth(point1, point2, lineColor, 5)
+}
+
+//DrawLineSegment 画一条从 (x1,y1) 到 (x2,y2)、线宽与颜色均可配置的直线,基于 Bresenham 算法光栅化.
+func (captcha *CaptchaImage) DrawLineSegment(x1, y1, x2, y2, width int, lineColor color.RGBA) *CaptchaImage {
+	return captcha.drawLineWidth(Point{X: x1, Y: y1}, Point{X: x2, Y: y2}, lineColor, width)
+}
+
+//drawLineWidth 基于 Bresenham 算法光栅化一条线段,斜率陡峭(|dy|>|dx|)时沿 y 轴步进,width 为线宽(像素).
+func (captcha *CaptchaImage) drawLineWidth(point1 Point, point2 Point, lineColor color.RGBA, width int) *CaptchaImage {
 	if captcha.Error != nil {
 		return captcha
 	}
-	dx := math.Abs(float64(point1.X - point2.X))
+	if width <= 0 {
+		width = 1
+	}
+	half := width / 2
+
+	x1, y1 := point1.X, point1.Y
+	x2, y2 := point2.X, point2.Y
+
+	dx := absInt(x2 - x1)
+	dy := absInt(y2 - y1)
+
+	//斜率陡峭时交换 x/y,统一沿较长的一轴步进,避免陡峭线段出现断点.
+	steep := dy > dx
+	if steep {
+		x1, y1 = y1, x1
+		x2, y2 = y2, x2
+		dx, dy = dy, dx
+	}
 
-	dy := math.Abs(float64(point2.Y - point1.Y))
 	sx, sy := 1, 1
-	if point1.X >= point2.X {
+	if x1 > x2 {
 		sx = -1
 	}
-	if point1.Y >= point2.Y {
+	if y1 > y2 {
 		sy = -1
 	}
-	err := dx - dy
-	//循环的画点直到到达结束坐标停止.
-	for {
-		captcha.nrgba.Set(point1.X, point1.Y, lineColor)
-		captcha.nrgba.Set(point1.X+1, point1.Y, lineColor)
-		captcha.nrgba.Set(point1.X-1, point1.Y, lineColor)
-		captcha.nrgba.Set(point1.X+2, point1.Y, lineColor)
-		captcha.nrgba.Set(point1.X-2, point1.Y, lineColor)
-		if point1.X == point2.X && point1.Y == point2.Y {
-			return captcha
+
+	err := dx / 2
+	y := y1
+
+	for x := x1; ; x += sx {
+		for i := -half; i <= half; i++ {
+			if steep {
+				captcha.nrgba.Set(y+i, x, lineColor)
+			} else {
+				captcha.nrgba.Set(x, y+i, lineColor)
+			}
 		}
-		e2 := err * 2
-		if e2 > -dy {
-			err -= dy
-			point1.X += sx
+
+		if x == x2 {
+			return captcha
 		}
-		if e2 < dx {
+
+		err -= dy
+		if err < 0 {
+			y += sy
 			err += dx
-			point1.Y += sy
 		}
 	}
 }
@@ -269,13 +312,13 @@ func (captcha *CaptchaImage) DrawNoise(complex CaptchaComplex) *CaptchaImage {
 
 	for i := 0; i < maxSize; i++ {
 
-		rw := rand.Intn(captcha.width)
-		rh := rand.Intn(captcha.height)
+		rw := captcha.safeIntn(captcha.width)
+		rh := captcha.safeIntn(captcha.height)
 
-		captcha.nrgba.Set(rw, rh, randColor())
-		size := rand.Intn(maxSize)
+		captcha.nrgba.Set(rw, rh, captcha.randColor())
+		size := captcha.safeIntn(maxSize)
 		if size%3 == 0 {
-			captcha.nrgba.Set(rw+1, rh+1, randColor())
+			captcha.nrgba.Set(rw+1, rh+1, captcha.randColor())
 		}
 	}
 	return captcha
@@ -297,27 +340,25 @@ func (captcha *CaptchaImage) DrawTextNoise(complex CaptchaComplex) *CaptchaImage
 
 	maxSize := (captcha.height * captcha.width) / density
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	c := freetype.NewContext()
 	c.SetDPI(*dpi)
 
 	c.SetClip(captcha.nrgba.Bounds())
 	c.SetDst(captcha.nrgba)
 	c.SetHinting(font.HintingFull)
-	rawFontSize := float64(captcha.height) / (1 + float64(rand.Intn(7))/float64(10))
+	rawFontSize := float64(captcha.height) / (1 + float64(captcha.safeIntn(7))/float64(10))
 
 	for i := 0; i < maxSize; i++ {
 
-		rw := rand.Intn(captcha.width)
-		rh := rand.Intn(captcha.height)
+		rw := captcha.safeIntn(captcha.width)
+		rh := captcha.safeIntn(captcha.height)
 
 		text := RandText(1)
-		fontSize := rawFontSize/2 + float64(rand.Intn(5))
+		fontSize := rawFontSize/2 + float64(captcha.safeIntn(5))
 
-		c.SetSrc(image.NewUniform(RandLightColor()))
+		c.SetSrc(image.NewUniform(captcha.randLightColor()))
 		c.SetFontSize(fontSize)
-		f, err := RandFontFamily()
+		f, err := captcha.randFont()
 
 		if err != nil {
 			captcha.Error = err
@@ -347,15 +388,17 @@ func (captcha *CaptchaImage) DrawText(text string) *CaptchaImage {
 	c.SetDst(captcha.nrgba)
 	c.SetHinting(font.HintingFull)
 
-	fontWidth := captcha.width / len(text)
+	//按 rune 数而非字节数计算宽度,避免中文等多字节字符挤在一起.
+	fontWidth := captcha.width / utf8.RuneCountInString(text)
 
-	for i, s := range text {
+	i := 0
+	for _, s := range text {
 
-		fontSize := float64(captcha.height) / (1 + float64(rand.Intn(7))/float64(9))
+		fontSize := float64(captcha.height) / (1 + float64(captcha.safeIntn(7))/float64(9))
 
-		c.SetSrc(image.NewUniform(randDeepColor()))
+		c.SetSrc(image.NewUniform(captcha.randDeepColor()))
 		c.SetFontSize(fontSize)
-		f, err := RandFontFamily()
+		f, err := captcha.randFont()
 
 		if err != nil {
 			captcha.Error = err
@@ -363,9 +406,14 @@ func (captcha *CaptchaImage) DrawText(text string) *CaptchaImage {
 		}
 		c.SetFont(f)
 
-		x := (fontWidth)*i + (fontWidth)/int(fontSize)
+		//int(fontSize) 在图片过矮(height<=1)时会截断为 0,此时按 1 处理以避免除零 panic.
+		intFontSize := int(fontSize)
+		if intFontSize <= 0 {
+			intFontSize = 1
+		}
+		x := (fontWidth)*i + (fontWidth)/intFontSize
 
-		y := 5 + rand.Intn(captcha.height/2) + int(fontSize/2)
+		y := 5 + captcha.safeIntn(captcha.height/2) + int(fontSize/2)
 
 		pt := freetype.Pt(x, y)
 
@@ -376,6 +424,7 @@ func (captcha *CaptchaImage) DrawText(text string) *CaptchaImage {
 		}
 		//pt.Y += c.PointToFixed(*size * *spacing)
 		//pt.X += c.PointToFixed(*size);
+		i++
 	}
 	return captcha
 
@@ -0,0 +1,32 @@
+package gocaptcha
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestDrawTextOnTinyImageDoesNotPanic(t *testing.T) {
+	captcha := New(100, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	captcha.DrawText("AB")
+	if captcha.Error != nil {
+		t.Fatalf("DrawText returned error: %v", captcha.Error)
+	}
+}
+
+func TestDrawLineSegmentRespectsWidth(t *testing.T) {
+	captcha := New(40, 40, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	lineColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
+
+	captcha.DrawLineSegment(5, 20, 35, 20, 7, lineColor)
+	if captcha.Error != nil {
+		t.Fatalf("DrawLineSegment returned error: %v", captcha.Error)
+	}
+
+	want := color.NRGBAModel.Convert(lineColor).(color.NRGBA)
+	if captcha.nrgba.NRGBAAt(20, 20) != want {
+		t.Fatal("expected the configured color to be drawn along the line")
+	}
+	if captcha.nrgba.NRGBAAt(20, 17) != want {
+		t.Fatal("expected a width-7 stroke to reach 3px above the center line")
+	}
+}
@@ -0,0 +1,164 @@
+package gocaptcha
+
+import (
+	"embed"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+)
+
+//go:embed fonts/*.ttf
+var embeddedFontFiles embed.FS
+
+//CharSource 验证码字符池的来源,用于替换默认的字母数字集合(中文/西里尔字母/emoji 等任意 unicode 字符).
+type CharSource interface {
+	//RandText 生成长度为 num 的随机文本.
+	RandText(num int) string
+}
+
+//charSetSource 基于固定字符集合按 rune 随机抽取的 CharSource 实现.
+type charSetSource struct {
+	runes []rune
+}
+
+//NewCharSource 基于给定的字符集合构建一个 CharSource,chars 可以是任意 unicode 字符串.
+func NewCharSource(chars string) CharSource {
+	return &charSetSource{runes: []rune(chars)}
+}
+
+func (s *charSetSource) RandText(num int) string {
+	if len(s.runes) == 0 {
+		return ""
+	}
+	text := make([]rune, num)
+	for i := 0; i < num; i++ {
+		text[i] = s.runes[safeIntn(len(s.runes))]
+	}
+	return string(text)
+}
+
+//FontsStorage 按名称提供 TTF 字体,替代原先的全局 fontFamily 切片 + RandFontFamily.
+type FontsStorage interface {
+	//Font 返回指定名称的字体.
+	Font(name string) (*truetype.Font, error)
+	//Names 返回当前已注册的全部字体名称.
+	Names() []string
+}
+
+//memoryFontsStorage 基于内存 map 的 FontsStorage 实现,支持运行时注册字体.
+type memoryFontsStorage struct {
+	mu    sync.RWMutex
+	fonts map[string]*truetype.Font
+}
+
+//NewFontsStorage 新建一个空的 FontsStorage,可通过 Register 动态注册 TTF 字体.
+func NewFontsStorage() *memoryFontsStorage {
+	return &memoryFontsStorage{fonts: make(map[string]*truetype.Font)}
+}
+
+//Register 向 storage 注册一个 TTF 字体,data 为字体文件原始内容.
+func (s *memoryFontsStorage) Register(name string, data []byte) error {
+	f, err := truetype.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fonts[name] = f
+	return nil
+}
+
+func (s *memoryFontsStorage) Font(name string) (*truetype.Font, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.fonts[name]
+	if !ok {
+		return nil, errors.New("gocaptcha: font not registered: " + name)
+	}
+	return f, nil
+}
+
+func (s *memoryFontsStorage) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.fonts))
+	for name := range s.fonts {
+		names = append(names, name)
+	}
+	return names
+}
+
+//defaultFontsStorage 使用 go:embed 内置的默认字体(DejaVu Sans Regular/Bold).
+var defaultFontsStorage = newDefaultFontsStorage()
+
+func newDefaultFontsStorage() *memoryFontsStorage {
+	storage := NewFontsStorage()
+
+	entries, err := embeddedFontFiles.ReadDir("fonts")
+	if err != nil {
+		return storage
+	}
+
+	for _, entry := range entries {
+		data, err := embeddedFontFiles.ReadFile("fonts/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".ttf")
+		_ = storage.Register(name, data)
+	}
+
+	return storage
+}
+
+//WithSource 设置验证码的字符池来源,用于替换默认的字母数字集合.
+func (captcha *CaptchaImage) WithSource(src CharSource) *CaptchaImage {
+	captcha.charSource = src
+	return captcha
+}
+
+//WithFonts 设置验证码使用的 FontsStorage 及可选字体名称,names 留空时使用 storage 内全部已注册字体.
+func (captcha *CaptchaImage) WithFonts(storage FontsStorage, names ...string) *CaptchaImage {
+	captcha.fontsStorage = storage
+	captcha.fontNames = names
+	return captcha
+}
+
+//randFont 按当前 FontsStorage 配置随机选取一个字体,未显式配置时使用内置的 defaultFontsStorage.
+func (captcha *CaptchaImage) randFont() (*truetype.Font, error) {
+	storage := captcha.fontsStorage
+	names := captcha.fontNames
+
+	if storage == nil {
+		storage = defaultFontsStorage
+	}
+	if len(names) == 0 {
+		names = storage.Names()
+	}
+	if len(names) == 0 {
+		return nil, errors.New("gocaptcha: no fonts registered")
+	}
+
+	return storage.Font(names[captcha.safeIntn(len(names))])
+}
+
+//DrawTextFromSource 使用当前 CharSource(未设置时使用默认字母数字池)生成随机文本并写入图片,返回生成的文本.
+func (captcha *CaptchaImage) DrawTextFromSource(num int) (text string, err error) {
+	if captcha.Error != nil {
+		return "", captcha.Error
+	}
+
+	src := captcha.charSource
+	if src == nil {
+		src = NewCharSource(txtChars)
+	}
+
+	text = src.RandText(num)
+	captcha.DrawText(text)
+	return text, captcha.Error
+}
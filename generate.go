@@ -0,0 +1,73 @@
+package gocaptcha
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"image/color"
+)
+
+//Captcha 面向服务端场景的验证码门面,串联图片生成与 Store 校验,调用方无需自行编写会话存储逻辑.
+type Captcha struct {
+	Width   int
+	Height  int
+	TextLen int
+	Complex CaptchaComplex
+	Store   Store
+}
+
+//NewCaptcha 新建一个 Captcha 门面,store 为 nil 时 Generate 只返回图片与答案,不做服务端存储.
+func NewCaptcha(width int, height int, store Store) *Captcha {
+	return &Captcha{
+		Width:   width,
+		Height:  height,
+		TextLen: 4,
+		Complex: CaptchaComplexMedium,
+		Store:   store,
+	}
+}
+
+//Generate 生成一张验证码图片,返回随机 id、可直接嵌入 <img src=...> 的 base64 data URL,以及明文答案.
+//当 Store 不为空时,会以 id 为键记录答案,供后续 Store.Verify 校验.
+func (c *Captcha) Generate() (id string, b64 string, answer string, err error) {
+	captcha := New(c.Width, c.Height, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	answer = RandText(c.TextLen)
+	captcha.DrawText(answer)
+	captcha.DrawNoise(c.Complex)
+	captcha.DrawSineLine()
+
+	if captcha.Error != nil {
+		return "", "", "", captcha.Error
+	}
+
+	buf := new(bytes.Buffer)
+	if err = captcha.SaveImage(buf, ImageFormatPng); err != nil {
+		return "", "", "", err
+	}
+
+	id, err = randID()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	b64 = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	if c.Store != nil {
+		if err = c.Store.Set(id, answer); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	return id, b64, answer, nil
+}
+
+//randID 生成一个随机十六进制 id,用于 Store 会话键.
+func randID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := cryptorand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,73 @@
+package gocaptcha
+
+import (
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+const (
+	//defaultGifFrames/defaultGifDelayCS SaveImage 用 ImageFormatGif 时的默认帧数与帧间隔(单位 10ms).
+	defaultGifFrames  = 8
+	defaultGifDelayCS = 8
+)
+
+//SaveAnimatedGIF 生成 frames 帧、每帧间隔 delayCS(单位:10ms)的动态 GIF.每帧在当前画面基础上叠加独立相位的正弦干扰线、
+//噪点,并轻微抖动整体位移,比单帧静态图更难被 OCR 直接识别,因为机器需要综合多帧画面才能还原出字符轮廓.
+func (captcha *CaptchaImage) SaveAnimatedGIF(w io.Writer, frames int, delayCS int) error {
+	if captcha.Error != nil {
+		return captcha.Error
+	}
+	if frames <= 0 {
+		frames = defaultGifFrames
+	}
+	if delayCS <= 0 {
+		delayCS = defaultGifDelayCS
+	}
+
+	anim := &gif.GIF{}
+
+	for i := 0; i < frames; i++ {
+		frame := captcha.renderGifFrame(i, frames)
+
+		paletted := image.NewPaletted(frame.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delayCS)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+//renderGifFrame 以当前画面为底图构造动画的第 index(共 frames)帧:整体做像素级抖动模拟字形晃动,
+//再叠加一条相位偏移的正弦干扰线与一层轻噪点.
+func (captcha *CaptchaImage) renderGifFrame(index int, frames int) *image.NRGBA {
+	bounds := captcha.nrgba.Bounds()
+	frame := image.NewNRGBA(bounds)
+
+	dx := captcha.safeIntn(3) - 1
+	dy := captcha.safeIntn(3) - 1
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			sx, sy := x-dx, y-dy
+			if (image.Point{X: sx, Y: sy}).In(bounds) {
+				frame.Set(x, y, captcha.nrgba.At(sx, sy))
+			} else {
+				frame.Set(x, y, captcha.nrgba.At(x, y))
+			}
+		}
+	}
+
+	phase := 2 * math.Pi * float64(index) / float64(frames)
+
+	frameCaptcha := &CaptchaImage{nrgba: frame, width: captcha.width, height: captcha.height, rnd: captcha.rnd}
+	frameCaptcha.sineLine(phase)
+	frameCaptcha.DrawNoise(CaptchaComplexLower)
+
+	return frame
+}
@@ -0,0 +1,110 @@
+package gocaptcha
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+//MathMode 算术验证码的运算符集合/难度.
+type MathMode int
+
+const (
+	//MathModeAddSub 仅加减法.
+	MathModeAddSub MathMode = iota
+	//MathModeAddSubMul 加减乘法.
+	MathModeAddSubMul
+	//MathModeAll 加减乘除全部运算符.
+	MathModeAll
+)
+
+const (
+	//DefaultMathOperandMin/DefaultMathOperandMax 算术验证码操作数的默认范围 [0, 10].
+	DefaultMathOperandMin = 0
+	DefaultMathOperandMax = 10
+)
+
+//mathOperators 根据 MathMode 返回可用的运算符.
+func mathOperators(mode MathMode) []byte {
+	switch mode {
+	case MathModeAddSub:
+		return []byte{'+', '-'}
+	case MathModeAddSubMul:
+		return []byte{'+', '-', '*'}
+	default:
+		return []byte{'+', '-', '*', '/'}
+	}
+}
+
+//genMathExpression 随机生成一个算术表达式及其答案,两个操作数均取自 [operandMin, operandMax].
+//operandMax<=operandMin 时回退到默认范围 [DefaultMathOperandMin, DefaultMathOperandMax].
+func genMathExpression(captcha *CaptchaImage, mode MathMode, operandMin, operandMax int) (expr string, answer int) {
+	if operandMax <= operandMin {
+		operandMin, operandMax = DefaultMathOperandMin, DefaultMathOperandMax
+	}
+	span := operandMax - operandMin + 1
+
+	ops := mathOperators(mode)
+	op := ops[captcha.safeIntn(len(ops))]
+
+	a := captcha.safeIntn(span) + operandMin
+	b := captcha.safeIntn(span) + operandMin
+
+	symbol := string(op)
+
+	switch op {
+	case '+':
+		answer = a + b
+	case '-':
+		//避免出现负数答案.
+		if b > a {
+			a, b = b, a
+		}
+		answer = a - b
+	case '*':
+		symbol = "×"
+		answer = a * b
+	case '/':
+		symbol = "÷"
+		//被除数(a)必须保持在 [operandMin, operandMax] 范围内,因此改为先定除数再定商,而不是事后把 a 放大.
+		divisor := b
+		if divisor <= 0 {
+			divisor = 1
+		}
+		quotientMax := operandMax / divisor
+		quotient := captcha.safeIntn(quotientMax + 1)
+		a = quotient * divisor
+		b = divisor
+		answer = quotient
+	}
+
+	return fmt.Sprintf("%d %s %d = ?", a, symbol, b), answer
+}
+
+//DrawMathExpression 生成一个算术表达式并写入图片,返回数值答案.操作数取自 [operandMin, operandMax].
+func (captcha *CaptchaImage) DrawMathExpression(mode MathMode, operandMin, operandMax int) (answer string, err error) {
+	if captcha.Error != nil {
+		return "", captcha.Error
+	}
+
+	expr, result := genMathExpression(captcha, mode, operandMin, operandMax)
+
+	captcha.DrawText(expr)
+	if captcha.Error != nil {
+		return "", captcha.Error
+	}
+
+	return strconv.Itoa(result), nil
+}
+
+//NewMathCaptcha 新建一个算术验证码图片,返回图片对象与数值答案.操作数取自 [operandMin, operandMax].
+func NewMathCaptcha(width int, height int, mode MathMode, operandMin, operandMax int) (captcha *CaptchaImage, answer string) {
+	captcha = New(width, height, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	answer, err := captcha.DrawMathExpression(mode, operandMin, operandMax)
+	if err != nil {
+		captcha.Error = err
+	}
+
+	return captcha, answer
+}
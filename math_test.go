@@ -0,0 +1,38 @@
+package gocaptcha
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"testing"
+)
+
+func TestDrawMathExpressionAnswerIsNumeric(t *testing.T) {
+	captcha := New(240, 80, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	answer, err := captcha.DrawMathExpression(MathModeAll, DefaultMathOperandMin, DefaultMathOperandMax)
+	if err != nil {
+		t.Fatalf("DrawMathExpression returned error: %v", err)
+	}
+	if _, err := strconv.Atoi(answer); err != nil {
+		t.Fatalf("answer %q is not numeric: %v", answer, err)
+	}
+}
+
+func TestGenMathExpressionKeepsOperandsInRange(t *testing.T) {
+	captcha := New(10, 10, color.RGBA{})
+
+	for i := 0; i < 200; i++ {
+		expr, _ := genMathExpression(captcha, MathModeAll, DefaultMathOperandMin, DefaultMathOperandMax)
+
+		var a, b int
+		var symbol string
+		if _, err := fmt.Sscanf(expr, "%d %s %d = ?", &a, &symbol, &b); err != nil {
+			t.Fatalf("unexpected expression format %q: %v", expr, err)
+		}
+
+		if a < DefaultMathOperandMin || a > DefaultMathOperandMax || b < DefaultMathOperandMin || b > DefaultMathOperandMax {
+			t.Fatalf("operand out of [%d, %d] range in %q", DefaultMathOperandMin, DefaultMathOperandMax, expr)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package gocaptcha
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+//captchaSeedCounter 配合纳秒时间戳为每个 CaptchaImage 派生独立的随机种子,避免同一纳秒内并发创建时种子碰撞.
+var captchaSeedCounter int64
+
+//nextSeed 返回一个用于初始化per-image *rand.Rand 的种子.
+func nextSeed() int64 {
+	return time.Now().UnixNano() + atomic.AddInt64(&captchaSeedCounter, 1)
+}
+
+//safeIntn 是 rand.Intn 的安全包装,n<=0 时返回 0 而不是 panic.
+//小尺寸图片(如 width<20)会使 first、captcha.height/2 等参数变为 0,直接调用 rand.Intn 会 panic.
+func safeIntn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return rand.Intn(n)
+}
+
+//safeIntn 是 CaptchaImage 私有的 rand.Intn 安全包装,使用该图片自己的 *rand.Rand,
+//使并发生成的多张验证码互不干扰、不争用全局随机源.
+func (captcha *CaptchaImage) safeIntn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return captcha.rnd.Intn(n)
+}
+
+//random 返回区间 [min, max) 内的随机浮点数,使用该图片自己的 *rand.Rand;max<=min 时返回 min.
+func (captcha *CaptchaImage) random(min, max int64) float64 {
+	if max <= min {
+		return float64(min)
+	}
+	return float64(min) + captcha.rnd.Float64()*float64(max-min)
+}
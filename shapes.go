@@ -0,0 +1,106 @@
+package gocaptcha
+
+import (
+	"image/color"
+	"math"
+)
+
+//absInt 返回整数绝对值.
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+//DrawCircle 使用中点圆算法(8 方向对称)画一个圆,fill 为 true 时填充圆面.
+func (captcha *CaptchaImage) DrawCircle(xc, yc, r int, fill bool, c color.RGBA) *CaptchaImage {
+	if captcha.Error != nil {
+		return captcha
+	}
+
+	x, y := 0, r
+	d := 1 - r
+
+	captcha.plotCirclePoints(xc, yc, x, y, c, fill)
+
+	limit := int(float64(r) / math.Sqrt2)
+	for x < limit {
+		if d < 0 {
+			d += 2*x + 3
+		} else {
+			d += 2*(x-y) + 5
+			y--
+		}
+		x++
+		captcha.plotCirclePoints(xc, yc, x, y, c, fill)
+	}
+
+	return captcha
+}
+
+//plotCirclePoints 画出圆上关于圆心 8 方向对称的点,fill 为 true 时在对称点之间补齐水平扫描线.
+func (captcha *CaptchaImage) plotCirclePoints(xc, yc, x, y int, c color.RGBA, fill bool) {
+	if !fill {
+		captcha.nrgba.Set(xc+x, yc+y, c)
+		captcha.nrgba.Set(xc-x, yc+y, c)
+		captcha.nrgba.Set(xc+x, yc-y, c)
+		captcha.nrgba.Set(xc-x, yc-y, c)
+		captcha.nrgba.Set(xc+y, yc+x, c)
+		captcha.nrgba.Set(xc-y, yc+x, c)
+		captcha.nrgba.Set(xc+y, yc-x, c)
+		captcha.nrgba.Set(xc-y, yc-x, c)
+		return
+	}
+
+	captcha.hLine(xc-x, xc+x, yc+y, c)
+	captcha.hLine(xc-x, xc+x, yc-y, c)
+	captcha.hLine(xc-y, xc+y, yc+x, c)
+	captcha.hLine(xc-y, xc+y, yc-x, c)
+}
+
+//hLine 在 y 行上画一条从 x1 到 x2(含端点)的水平线.
+func (captcha *CaptchaImage) hLine(x1, x2, y int, c color.RGBA) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	for x := x1; x <= x2; x++ {
+		captcha.nrgba.Set(x, y, c)
+	}
+}
+
+//ShowLineOptions 干扰线组合位标志,配合 DrawWithOptions 声明式地叠加干扰效果,替代手动链式调用各个 DrawXxx.
+type ShowLineOptions int
+
+const (
+	//OptionShowHollowLine 叠加空白曲线(DrawHollowLine).
+	OptionShowHollowLine ShowLineOptions = 1 << iota
+	//OptionShowSlimeLine 叠加粘滑干扰直线(DrawLine).
+	OptionShowSlimeLine
+	//OptionShowSineLine 叠加正弦曲线(DrawSineLine).
+	OptionShowSineLine
+	//OptionShowBorder 叠加边框(DrawBorder).
+	OptionShowBorder
+)
+
+//DrawWithOptions 按 ShowLineOptions 位标志组合叠加干扰线,borderColor 仅在设置 OptionShowBorder 时生效.
+func (captcha *CaptchaImage) DrawWithOptions(options ShowLineOptions, borderColor color.RGBA) *CaptchaImage {
+	if captcha.Error != nil {
+		return captcha
+	}
+
+	if options&OptionShowHollowLine != 0 {
+		captcha.DrawHollowLine()
+	}
+	if options&OptionShowSlimeLine != 0 {
+		captcha.DrawLine(2)
+	}
+	if options&OptionShowSineLine != 0 {
+		captcha.DrawSineLine()
+	}
+	if options&OptionShowBorder != 0 {
+		captcha.DrawBorder(borderColor)
+	}
+
+	return captcha
+}
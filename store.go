@@ -0,0 +1,122 @@
+package gocaptcha
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Store 验证码答案的服务端会话存储,Generate 生成的 id 与答案通过它完成校验配对.
+type Store interface {
+	//Set 记录 id 对应的答案.
+	Set(id, answer string) error
+	//Verify 校验 id 对应的答案是否匹配,clear 为 true 时无论校验是否成功都应在匹配后清除该记录,防止重放.
+	Verify(id, answer string, clear bool) bool
+}
+
+//lruEntry LRU 链表节点存储的内容.
+type lruEntry struct {
+	id     string
+	answer string
+}
+
+//lruStore 基于内存的 LRU Store,容量超出后淘汰最久未使用的记录.
+type lruStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+//NewMemoryStore 新建一个容量为 capacity 的内存 LRU Store,capacity<=0 时使用默认值 256.
+func NewMemoryStore(capacity int) Store {
+	if capacity <= 0 {
+		capacity = 256
+	}
+	return &lruStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (s *lruStore) Set(id, answer string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[id]; ok {
+		el.Value.(*lruEntry).answer = answer
+		s.order.MoveToFront(el)
+		return nil
+	}
+
+	el := s.order.PushFront(&lruEntry{id: id, answer: answer})
+	s.items[id] = el
+
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		if oldest != nil {
+			s.order.Remove(oldest)
+			delete(s.items, oldest.Value.(*lruEntry).id)
+		}
+	}
+	return nil
+}
+
+func (s *lruStore) Verify(id, answer string, clear bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[id]
+	if !ok {
+		return false
+	}
+
+	matched := strings.EqualFold(el.Value.(*lruEntry).answer, answer)
+	if clear {
+		s.order.Remove(el)
+		delete(s.items, id)
+	}
+	return matched
+}
+
+//RedisClient 描述 redisStore 依赖的最小命令集合,以便接入 go-redis 等任意 Redis 客户端而不引入具体依赖.
+type RedisClient interface {
+	Set(key string, value string, expiration time.Duration) error
+	Get(key string) (string, error)
+	Del(key string) error
+}
+
+//redisStore 基于 Redis 的 Store,适合多实例部署下共享验证码会话.
+type redisStore struct {
+	client RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+//NewRedisStore 新建一个 Redis Store,prefix 用于给 key 加前缀隔离命名空间,ttl<=0 时不设置过期时间.
+func NewRedisStore(client RedisClient, prefix string, ttl time.Duration) Store {
+	return &redisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *redisStore) key(id string) string {
+	return s.prefix + id
+}
+
+func (s *redisStore) Set(id, answer string) error {
+	return s.client.Set(s.key(id), answer, s.ttl)
+}
+
+func (s *redisStore) Verify(id, answer string, clear bool) bool {
+	val, err := s.client.Get(s.key(id))
+	if err != nil || val == "" {
+		return false
+	}
+
+	matched := strings.EqualFold(val, answer)
+	if clear {
+		_ = s.client.Del(s.key(id))
+	}
+	return matched
+}
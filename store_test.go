@@ -0,0 +1,54 @@
+package gocaptcha
+
+import "testing"
+
+func TestMemoryStoreSetVerify(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	if err := store.Set("id1", "answer"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	if !store.Verify("id1", "ANSWER", false) {
+		t.Fatal("Verify should match case-insensitively")
+	}
+
+	if !store.Verify("id1", "answer", true) {
+		t.Fatal("Verify with clear=true should still match on a present record")
+	}
+
+	if store.Verify("id1", "answer", false) {
+		t.Fatal("record should have been cleared after Verify(clear=true)")
+	}
+}
+
+func TestMemoryStoreClearsOnWrongAnswer(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	if err := store.Set("id1", "answer"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	if store.Verify("id1", "wrong", true) {
+		t.Fatal("Verify should not match a wrong answer")
+	}
+
+	if store.Verify("id1", "answer", false) {
+		t.Fatal("record should have been cleared even though the guess was wrong, to stop retries")
+	}
+}
+
+func TestMemoryStoreEvictsOldest(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	store.Set("id1", "a")
+	store.Set("id2", "b")
+	store.Set("id3", "c")
+
+	if store.Verify("id1", "a", false) {
+		t.Fatal("oldest record should have been evicted once capacity was exceeded")
+	}
+	if !store.Verify("id3", "c", false) {
+		t.Fatal("most recently set record should still be present")
+	}
+}
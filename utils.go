@@ -3,15 +3,14 @@ package gocaptcha
 import (
 	"image/color"
 	"math"
-	"math/rand"
 )
 
-//随机生成深色系.
-func randDeepColor() color.RGBA {
+//随机生成深色系,使用该图片自己的 *rand.Rand,避免并发生成时争用全局随机源.
+func (captcha *CaptchaImage) randDeepColor() color.RGBA {
 
-	randColor := randColor()
+	randColor := captcha.randColor()
 
-	increase := float64(30 + rand.Intn(255))
+	increase := float64(30 + captcha.safeIntn(255))
 
 	red := math.Abs(math.Min(float64(randColor.R)-increase, 255))
 
@@ -21,22 +20,22 @@ func randDeepColor() color.RGBA {
 	return color.RGBA{R: uint8(red), G: uint8(green), B: uint8(blue), A: uint8(255)}
 }
 
-//随机生成浅色.
-func RandLightColor() color.RGBA {
+//随机生成浅色,使用该图片自己的 *rand.Rand,避免并发生成时争用全局随机源.
+func (captcha *CaptchaImage) randLightColor() color.RGBA {
 
-	red := rand.Intn(55) + 200
-	green := rand.Intn(55) + 200
-	blue := rand.Intn(55) + 200
+	red := captcha.safeIntn(55) + 200
+	green := captcha.safeIntn(55) + 200
+	blue := captcha.safeIntn(55) + 200
 
 	return color.RGBA{R: uint8(red), G: uint8(green), B: uint8(blue), A: uint8(255)}
 }
 
-//生成随机颜色.
-func randColor() color.RGBA {
+//生成随机颜色,使用该图片自己的 *rand.Rand,避免并发生成时争用全局随机源.
+func (captcha *CaptchaImage) randColor() color.RGBA {
 
-	red := rand.Intn(255)
-	green := rand.Intn(255)
-	blue := rand.Intn(255)
+	red := captcha.safeIntn(255)
+	green := captcha.safeIntn(255)
+	blue := captcha.safeIntn(255)
 	if (red + green) > 400 {
 		blue = 0
 	} else {
@@ -50,12 +49,7 @@ func randColor() color.RGBA {
 
 //生成随机字体.
 func RandText(num int) string {
-	textNum := len(txtChars)
-	text := ""
-	for i := 0; i < num; i++ {
-		text = text + string(txtChars[rand.Intn(textNum)])
-	}
-	return text
+	return NewCharSource(txtChars).RandText(num)
 }
 
 // 颜色代码转换为RGB